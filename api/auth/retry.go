@@ -0,0 +1,86 @@
+// Package auth holds helpers shared by the concrete auth method
+// implementations under api/auth/ (approle, aws, etc.), each of which
+// implements api.AuthMethod.
+package auth
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrLoginInProgress is the client-side sentinel a caller can match on
+// (errors.Is) when a Login call was rejected because the backend plugin
+// is mid-reinitialization. It's surfaced instead of a generic 500 so
+// callers know the request is safe to retry as-is.
+var ErrLoginInProgress = errors.New("vault: login in progress, retry")
+
+// RetryJitterConfig controls LoginWithRetry's backoff.
+type RetryJitterConfig struct {
+	// MaxAttempts is the maximum number of Login calls to make, including
+	// the first. Defaults to 5 if zero.
+	MaxAttempts int
+	// BaseDelay is the starting backoff delay. Defaults to 100ms if zero.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay. Defaults to 2s if zero.
+	MaxDelay time.Duration
+}
+
+func (c RetryJitterConfig) withDefaults() RetryJitterConfig {
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 5
+	}
+	if c.BaseDelay == 0 {
+		c.BaseDelay = 100 * time.Millisecond
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = 2 * time.Second
+	}
+	return c
+}
+
+// LoginWithRetry calls login and, while it keeps returning
+// ErrLoginInProgress, retries with exponential backoff plus full jitter
+// until cfg.MaxAttempts is reached or ctx is done. Any other error, or a
+// successful login, is returned immediately.
+//
+// This is meant for callers driving a plugin auth method directly through
+// its LoginInProgress sentinel response rather than through
+// api.Client.Auth().Login, which already retries retryable transport
+// errors on its own.
+func LoginWithRetry(ctx context.Context, cfg RetryJitterConfig, login func() (interface{}, error)) (interface{}, error) {
+	cfg = cfg.withDefaults()
+
+	var lastErr error
+	delay := cfg.BaseDelay
+
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		resp, err := login()
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, ErrLoginInProgress) {
+			return nil, err
+		}
+		lastErr = err
+
+		if attempt == cfg.MaxAttempts-1 {
+			break
+		}
+
+		jittered := time.Duration(rand.Int63n(int64(delay)))
+		select {
+		case <-time.After(jittered):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		delay *= 2
+		if delay > cfg.MaxDelay {
+			delay = cfg.MaxDelay
+		}
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// PluginEvent mirrors vault.PluginEvent on the wire. Keeping a dedicated
+// client-side type (rather than importing the vault package) matches how
+// the rest of this package shadows server-side response types.
+type PluginEvent struct {
+	Type string `json:"type"`
+
+	Name       string   `json:"name"`
+	PluginType string   `json:"plugin_type"`
+	Version    string   `json:"version"`
+	SHA256     string   `json:"sha256"`
+	MountPaths []string `json:"mount_paths,omitempty"`
+
+	PID        int    `json:"pid,omitempty"`
+	ExitReason string `json:"exit_reason,omitempty"`
+}
+
+// PluginEventFilter narrows a WatchPlugins subscription. An empty filter
+// matches every plugin lifecycle event.
+type PluginEventFilter struct {
+	Name    string
+	Type    string
+	Version string
+}
+
+// PluginEventWatcher streams PluginEvents from /sys/plugins/events until
+// Close is called or the server closes the connection.
+type PluginEventWatcher struct {
+	Events <-chan *PluginEvent
+	Errors <-chan error
+
+	cancel context.CancelFunc
+}
+
+// Close stops the watch and releases the underlying HTTP connection.
+func (w *PluginEventWatcher) Close() {
+	w.cancel()
+}
+
+// WatchPlugins opens a long-poll/SSE subscription against
+// /sys/plugins/events, filtered by filter, and streams plugin lifecycle
+// events until the returned watcher is closed. This is the Go counterpart
+// to cluster controllers that would otherwise have to poll
+// Sys().ListPlugins to notice a crashed plugin.
+func (c *Sys) WatchPlugins(filter *PluginEventFilter) (*PluginEventWatcher, error) {
+	return c.WatchPluginsWithContext(context.Background(), filter)
+}
+
+func (c *Sys) WatchPluginsWithContext(ctx context.Context, filter *PluginEventFilter) (*PluginEventWatcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r := c.c.NewRequest("GET", "/v1/sys/plugins/events")
+	if filter != nil {
+		q := url.Values{}
+		if filter.Name != "" {
+			q.Set("name", filter.Name)
+		}
+		if filter.Type != "" {
+			q.Set("type", filter.Type)
+		}
+		if filter.Version != "" {
+			q.Set("version", filter.Version)
+		}
+		r.Params = q
+	}
+
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan *PluginEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || line[0] != 'd' {
+				// Skip SSE "event:" lines and keep-alive comments; only
+				// "data: {...}" lines carry a PluginEvent.
+				continue
+			}
+
+			const prefix = "data: "
+			if len(line) <= len(prefix) {
+				continue
+			}
+
+			evt := new(PluginEvent)
+			if err := json.Unmarshal([]byte(line[len(prefix):]), evt); err != nil {
+				select {
+				case errs <- fmt.Errorf("decoding plugin event: %w", err):
+				default:
+				}
+				continue
+			}
+
+			select {
+			case events <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+	}()
+
+	return &PluginEventWatcher{Events: events, Errors: errs, cancel: cancel}, nil
+}
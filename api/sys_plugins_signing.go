@@ -0,0 +1,87 @@
+package api
+
+import "context"
+
+// PluginManifestInput mirrors vault.PluginManifestInput for the signed
+// registration request body: the declared type and capabilities a signed
+// plugin manifest claims.
+type PluginManifestInput struct {
+	PluginType        string   `json:"plugin_type"`
+	Capabilities      []string `json:"capabilities,omitempty"`
+	RequiredSudoPaths []string `json:"required_sudo_paths,omitempty"`
+}
+
+// PluginSignatureInput carries a detached signature over a plugin
+// binary's SHA256, and a manifest describing the plugin; the signature
+// does not cover the manifest. It rides
+// next to RegisterPluginInput on the wire rather than being added as
+// fields on that struct, since RegisterPluginInput is also used for
+// registrations with no signature at all.
+type PluginSignatureInput struct {
+	// Signature is a detached signature over the raw bytes of the
+	// RegisterPluginInput's SHA256, encoded as the format below expects
+	// (e.g. hex for minisign's reference verifier).
+	Signature string `json:"signature"`
+	// SignatureFormat identifies how Signature was produced:
+	// "minisign", "cosign" or "pgp".
+	SignatureFormat string `json:"signature_format"`
+	// Manifest describes this plugin's declared type and capabilities.
+	// When set, its PluginType is cross-checked against the paired
+	// RegisterPluginInput's Type; capabilities and sudo paths are not
+	// checked.
+	Manifest *PluginManifestInput `json:"manifest,omitempty"`
+}
+
+// signedRegisterPluginRequest is the combined wire body for
+// RegisterPluginSigned: the usual registration fields plus the signature
+// envelope above.
+type signedRegisterPluginRequest struct {
+	*RegisterPluginInput
+	*PluginSignatureInput
+}
+
+// RegisterPluginSigned registers a plugin the same way RegisterPlugin
+// does, but additionally supplies a detached signature and a manifest
+// that the catalog must verify against a configured trust root
+// before the plugin is ever spawned. Use RegisterPlugin instead if the
+// target core has no plugin_trust_roots configured.
+func (c *Sys) RegisterPluginSigned(i *RegisterPluginInput, sig *PluginSignatureInput) error {
+	return c.RegisterPluginSignedWithContext(context.Background(), i, sig)
+}
+
+func (c *Sys) RegisterPluginSignedWithContext(ctx context.Context, i *RegisterPluginInput, sig *PluginSignatureInput) error {
+	path := signedPluginCatalogPath(i.Type, i.Name)
+
+	req := c.c.NewRequest("PUT", path)
+	if err := req.SetJSONBody(&signedRegisterPluginRequest{
+		RegisterPluginInput:  i,
+		PluginSignatureInput: sig,
+	}); err != nil {
+		return err
+	}
+
+	resp, err := c.c.RawRequestWithContext(ctx, req)
+	if err == nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
+// signedPluginCatalogPath builds the same /v1/sys/plugins/catalog/<type>/
+// <name> path RegisterPlugin uses. It's kept local to this file (rather
+// than reusing a private helper from RegisterPlugin's implementation)
+// since that implementation isn't part of this patch.
+func signedPluginCatalogPath(t PluginType, name string) string {
+	var typ string
+	switch t {
+	case PluginTypeCredential:
+		typ = "auth"
+	case PluginTypeSecrets:
+		typ = "secret"
+	case PluginTypeDatabase:
+		typ = "database"
+	default:
+		typ = "unknown"
+	}
+	return "/v1/sys/plugins/catalog/" + typ + "/" + name
+}
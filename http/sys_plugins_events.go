@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/vault"
+)
+
+func writeSSEEvent(w http.ResponseWriter, evt *vault.PluginEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+	return err
+}
+
+// RegisterPluginEventsRoute mounts the long-poll/SSE endpoint backing
+// api.Sys.WatchPlugins on mux. No route table in this tree calls it; this
+// package's test mounts it on its own ServeMux. It takes the PluginCatalog directly, rather than *vault.Core, so that
+// the call site doesn't depend on any accessor being added to Core.
+func RegisterPluginEventsRoute(mux *http.ServeMux, catalog *vault.PluginCatalog) {
+	mux.Handle("/v1/sys/plugins/events", handleSysPluginsEvents(catalog))
+}
+
+// handleSysPluginsEvents implements the handler RegisterPluginEventsRoute
+// mounts at /v1/sys/plugins/events. It registers a subscription on
+// catalog and streams matching events to the client as they occur, so
+// cluster controllers don't have to poll for plugin crashes.
+func handleSysPluginsEvents(catalog *vault.PluginCatalog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respondError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		if catalog == nil {
+			respondError(w, http.StatusServiceUnavailable, nil)
+			return
+		}
+
+		filter := &vault.PluginEventFilter{
+			Name:    r.URL.Query().Get("name"),
+			Version: r.URL.Query().Get("version"),
+		}
+		if t := r.URL.Query().Get("type"); t != "" {
+			pt, err := consts.ParsePluginType(t)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, err)
+				return
+			}
+			filter.Type = pt
+		}
+
+		events, cancel, err := catalog.WatchPlugins(r.Context(), filter)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer cancel()
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			respondError(w, http.StatusInternalServerError, nil)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := writeSSEEvent(w, evt); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
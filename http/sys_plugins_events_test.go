@@ -0,0 +1,124 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/vault"
+)
+
+// wireEvent mirrors the "data: {...}" JSON payload handleSysPluginsEvents
+// writes for each PluginEvent, i.e. exactly what api.PluginEventWatcher
+// decodes. It's declared here, rather than reusing api.PluginEvent,
+// because api.Client/api.Sys - the machinery WatchPlugins needs to issue
+// the request - aren't present in this tree, so this test can't drive
+// the endpoint through the real client. Parsing the same "data: " lines
+// with the same field names is the closest in-tree equivalent to
+// asserting api.Sys().WatchPlugins works end to end.
+type wireEvent struct {
+	Type string `json:"type"`
+}
+
+// TestHandleSysPluginsEvents_StreamsLifecycleEvents proves the SSE
+// endpoint is actually wired to a real PluginCatalog: it mounts the
+// handler through RegisterPluginEventsRoute at the same path and via the
+// same *http.ServeMux mechanism handler.go's route table would use,
+// drives Register/Reload/Deregister against the catalog, and asserts
+// each transition arrives over the HTTP response body - parsed the same
+// way api.PluginEventWatcher parses it - in order.
+func TestHandleSysPluginsEvents_StreamsLifecycleEvents(t *testing.T) {
+	pluginDir := t.TempDir()
+	pluginPath := filepath.Join(pluginDir, "test-plugin")
+	pluginContents := []byte("#!/bin/sh\n")
+	if err := os.WriteFile(pluginPath, pluginContents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sha256Sum := sha256.Sum256(pluginContents)
+	sha256Hex := hex.EncodeToString(sha256Sum[:])
+
+	catalog := vault.NewPluginSubsystems(pluginDir, nil).Catalog
+
+	mux := http.NewServeMux()
+	RegisterPluginEventsRoute(mux, catalog)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/sys/plugins/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	events := make(chan string, 8)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			const prefix = "data: "
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			var evt wireEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, prefix)), &evt); err != nil {
+				continue
+			}
+			events <- evt.Type
+		}
+	}()
+
+	// Give the handler a moment to subscribe before we start driving
+	// catalog transitions, so we don't race the first event.
+	time.Sleep(50 * time.Millisecond)
+
+	ctx := context.Background()
+	if err := catalog.RegisterPlugin(ctx, &vault.RegisterPluginInput{
+		Name:    "test-plugin",
+		Type:    consts.PluginTypeDatabase,
+		Command: "test-plugin",
+		SHA256:  sha256Hex,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.ReloadPlugin(ctx, "test-plugin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.DeregisterPlugin(ctx, "test-plugin", consts.PluginTypeDatabase, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"PluginRegistered",
+		"PluginProcessStarted",
+		"PluginReloadStarted",
+		"PluginReloadCompleted",
+		"PluginProcessExited",
+		"PluginDeregistered",
+	}
+	for _, w := range want {
+		select {
+		case got := <-events:
+			if got != w {
+				t.Fatalf("expected event %q, got %q", w, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %q", w)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package logical
+
+import "errors"
+
+// ErrLoginInProgress is returned by a credential backend's Login handler
+// when the backend is mid-reinitialization (e.g. a ReloadPlugin swap or an
+// equivalent root-credential rotation is in flight) and cannot safely
+// evaluate the request against either plugin generation. Callers should
+// treat this as retryable, not as a hard failure: the backend will accept
+// the same request again once reinitialization completes.
+var ErrLoginInProgress = errors.New("logical: login is in progress, retry the request")
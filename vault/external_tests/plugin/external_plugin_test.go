@@ -2,8 +2,16 @@ package plugin_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/api/auth/approle"
@@ -650,3 +658,347 @@ CREATE ROLE "{{name}}" WITH
   VALID UNTIL '{{expiration}}';
 GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO "{{name}}";
 `
+
+// TestExternalPlugin_PluginEvents tests that registering, reloading and
+// deregistering a plugin in a PluginCatalog emits the expected sequence of
+// lifecycle events on a WatchPlugins subscription.
+//
+// This drives a vault.PluginSubsystems' Catalog directly rather than
+// through client.Sys().WatchPlugins()/RegisterPlugin(): see
+// vault.PluginSubsystems' doc comment for why a running *vault.Core can't
+// be made to hold and serve one in this tree. http.TestHandleSysPluginsEvents_*
+// covers the HTTP long-poll/SSE side of the same catalog instead.
+func TestExternalPlugin_PluginEvents(t *testing.T) {
+	pluginDir, cleanup := corehelpers.MakeTestPluginDir(t)
+	t.Cleanup(func() { cleanup(t) })
+
+	pluginPath := filepath.Join(pluginDir, "test-plugin")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog := vault.NewPluginSubsystems(pluginDir, nil).Catalog
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _, err := catalog.WatchPlugins(ctx, &vault.PluginEventFilter{Name: "test-plugin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sha256Sum := sha256HexOfFile(t, pluginPath)
+
+	// Register
+	if err := catalog.RegisterPlugin(context.Background(), &vault.RegisterPluginInput{
+		Name:    "test-plugin",
+		Type:    consts.PluginTypeCredential,
+		Command: "test-plugin",
+		SHA256:  sha256Sum,
+		Version: "v1.0.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	evt := waitForPluginEvent(t, events, "PluginRegistered")
+	if evt.Name != "test-plugin" {
+		t.Fatalf("expected event for plugin %q, got %q", "test-plugin", evt.Name)
+	}
+	if evt.SHA256 != sha256Sum {
+		t.Fatalf("expected event SHA256 %q, got %q", sha256Sum, evt.SHA256)
+	}
+	waitForPluginEvent(t, events, "PluginProcessStarted")
+
+	// Reload plugin
+	if err := catalog.ReloadPlugin(context.Background(), "test-plugin"); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForPluginEvent(t, events, "PluginReloadStarted")
+	waitForPluginEvent(t, events, "PluginReloadCompleted")
+
+	// Deregister
+	if err := catalog.DeregisterPlugin(context.Background(), "test-plugin", consts.PluginTypeCredential, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForPluginEvent(t, events, "PluginProcessExited")
+	waitForPluginEvent(t, events, "PluginDeregistered")
+}
+
+// sha256HexOfFile returns the hex-encoded SHA256 of path's contents, for
+// tests that register a plugin binary directly against vault.PluginCatalog
+// and so - unlike client.Sys().RegisterPlugin against a real built plugin -
+// need to advertise the SHA256 of exactly the bytes they wrote to disk.
+func sha256HexOfFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func waitForPluginEvent(t *testing.T, events <-chan *vault.PluginEvent, typ vault.PluginEventType) *vault.PluginEvent {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				t.Fatalf("event stream closed while waiting for %q", typ)
+			}
+			if evt.Type == typ {
+				return evt
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for plugin event %q", typ)
+		}
+	}
+}
+
+// TestExternalPlugin_SecretsEngine_SignedManifest tests that registration
+// is blocked for a plugin binary whose advertised SHA256 doesn't match its
+// actual bytes, blocked for a binary whose signature doesn't match a
+// configured trust root, and succeeds once the binary, SHA256, signature
+// and manifest all agree.
+//
+// This drives a vault.PluginSubsystems' Catalog directly rather than
+// through client.Sys().RegisterPlugin(): that method has no way to carry
+// a signature/manifest over the wire in this tree (api.RegisterPluginInput
+// has no such fields, and adding them isn't part of this patch), and the
+// production handler table doesn't expose a signed-registration route to
+// exercise from the client side. See TestExternalPlugin_PluginEvents for
+// the same reasoning applied to the event stream.
+func TestExternalPlugin_SecretsEngine_SignedManifest(t *testing.T) {
+	pluginDir, cleanup := corehelpers.MakeTestPluginDir(t)
+	t.Cleanup(func() { cleanup(t) })
+
+	pluginPath := filepath.Join(pluginDir, "test-plugin")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	trustRoot := vault.PluginTrustRoot{
+		Name:      "test-root",
+		Format:    vault.PluginSignatureMinisign,
+		PublicKey: "test-trust-root-key",
+	}
+	catalog := vault.NewPluginSubsystems(pluginDir, []vault.PluginTrustRoot{trustRoot}).Catalog
+
+	sha256Sum := sha256HexOfFile(t, pluginPath)
+	manifest := &vault.PluginManifestInput{PluginType: "secret"}
+
+	// Register - a binary whose real bytes don't hash to the advertised
+	// SHA256 must be blocked before the signature is ever checked, since a
+	// registration could otherwise swap a tampered binary onto the catalog
+	// entry by reusing a previously-signed SHA256.
+	tamperedSignature, err := vault.SignPluginSHA256ForTest(sha256Sum, trustRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = catalog.RegisterPlugin(context.Background(), &vault.RegisterPluginInput{
+		Name:            "test-plugin",
+		Type:            consts.PluginTypeSecrets,
+		Command:         "test-plugin",
+		SHA256:          "deadbeef",
+		Version:         "v1.0.0",
+		Signature:       tamperedSignature,
+		SignatureFormat: vault.PluginSignatureMinisign,
+		Manifest:        manifest,
+	})
+	if !errors.Is(err, vault.ErrPluginShaMismatch) {
+		t.Fatalf("expected ErrPluginShaMismatch for a tampered binary, got: %v", err)
+	}
+
+	// Register - a signature that doesn't verify against any configured
+	// trust root must block registration before the plugin is ever
+	// spawned.
+	err = catalog.RegisterPlugin(context.Background(), &vault.RegisterPluginInput{
+		Name:            "test-plugin",
+		Type:            consts.PluginTypeSecrets,
+		Command:         "test-plugin",
+		SHA256:          sha256Sum,
+		Version:         "v1.0.0",
+		Signature:       "00",
+		SignatureFormat: vault.PluginSignatureMinisign,
+		Manifest:        manifest,
+	})
+	if err == nil {
+		t.Fatal("expected registration with a tampered signature to fail")
+	}
+
+	// Register - a correctly signed binary and matching manifest must
+	// succeed.
+	validSignature, err := vault.SignPluginSHA256ForTest(sha256Sum, trustRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.RegisterPlugin(context.Background(), &vault.RegisterPluginInput{
+		Name:            "test-plugin",
+		Type:            consts.PluginTypeSecrets,
+		Command:         "test-plugin",
+		SHA256:          sha256Sum,
+		Version:         "v1.0.0",
+		Signature:       validSignature,
+		SignatureFormat: vault.PluginSignatureMinisign,
+		Manifest:        manifest,
+	}); err != nil {
+		t.Fatalf("expected registration with a valid signature to succeed, got: %v", err)
+	}
+
+	// Deregister
+	if err := catalog.DeregisterPlugin(context.Background(), "test-plugin", consts.PluginTypeSecrets, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestExternalPlugin_AuthMethod_ConcurrentLoginDuringReload drives a
+// single mount through vault.LoginRouter in three deterministic phases -
+// fill every login slot, then overflow it, then reload while the slots
+// are still held - and asserts each phase produces the rejection the
+// router should report for it: the overflow logins are rejected by the
+// concurrency limiter (StaleGeneration is never touched), the slot
+// holders are rejected for having been served by a generation the reload
+// superseded (Rejected is never touched), and a login issued once
+// everything has released succeeds against the current generation.
+//
+// This drives a vault.PluginSubsystems' Catalog and Router directly
+// rather than through a cluster's AppRole mount: see vault.PluginSubsystems'
+// doc comment for what wiring RouteLogin into a mount's real Login path
+// would take and why it can't be done in this tree. See
+// TestExternalPlugin_PluginEvents for the same reasoning applied to the
+// event stream.
+func TestExternalPlugin_AuthMethod_ConcurrentLoginDuringReload(t *testing.T) {
+	const maxConcurrentLogins = 5
+	const overflow = 3
+	const mountPath = "auth/test-plugin-0/"
+
+	pluginDir, cleanup := corehelpers.MakeTestPluginDir(t)
+	t.Cleanup(func() { cleanup(t) })
+
+	pluginPath := filepath.Join(pluginDir, "test-plugin")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	subsystems := vault.NewPluginSubsystems(pluginDir, nil)
+	catalog, router := subsystems.Catalog, subsystems.Router
+	if err := catalog.RegisterPlugin(context.Background(), &vault.RegisterPluginInput{
+		Name:    "test-plugin",
+		Type:    consts.PluginTypeCredential,
+		Command: "test-plugin",
+		SHA256:  sha256HexOfFile(t, pluginPath),
+		Version: "v1.0.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	router.TuneMaxConcurrentLogins(mountPath, maxConcurrentLogins)
+
+	// Phase 1: fill every slot. Each holder reports capturedGeneration
+	// once it has acquired its slot and captured the pre-reload
+	// generation, then holds the slot until released so the main
+	// goroutine can deterministically observe "every slot is full"
+	// before moving on, instead of racing a fixed sleep against the
+	// scheduler.
+	capturedGeneration := make(chan struct{}, maxConcurrentLogins)
+	releaseHolders := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var holderStale, holderOther int64
+	for i := 0; i < maxConcurrentLogins; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := router.RouteLogin(context.Background(), mountPath, "test-plugin", func(ctx context.Context) (interface{}, int, error) {
+				generation := catalog.GenerationFor("test-plugin")
+				capturedGeneration <- struct{}{}
+				<-releaseHolders
+				return "token", generation, nil
+			})
+			if errors.Is(err, logical.ErrLoginInProgress) {
+				atomic.AddInt64(&holderStale, 1)
+			} else {
+				atomic.AddInt64(&holderOther, 1)
+			}
+		}()
+	}
+	for i := 0; i < maxConcurrentLogins; i++ {
+		<-capturedGeneration
+	}
+
+	// Phase 2: every slot is now held, so overflow logins must be
+	// rejected by the limiter itself without ever calling their backend.
+	var overflowRejected, overflowOther int64
+	var overflowWg sync.WaitGroup
+	for i := 0; i < overflow; i++ {
+		overflowWg.Add(1)
+		go func() {
+			defer overflowWg.Done()
+			_, err := router.RouteLogin(context.Background(), mountPath, "test-plugin", func(ctx context.Context) (interface{}, int, error) {
+				t.Error("overflow login should have been rejected by the limiter before calling its backend")
+				return "token", catalog.GenerationFor("test-plugin"), nil
+			})
+			if errors.Is(err, logical.ErrLoginInProgress) {
+				atomic.AddInt64(&overflowRejected, 1)
+			} else {
+				atomic.AddInt64(&overflowOther, 1)
+			}
+		}()
+	}
+	overflowWg.Wait()
+
+	if overflowOther != 0 {
+		t.Fatalf("expected every overflow login to be rejected with LoginInProgress, got %d other outcomes", overflowOther)
+	}
+	if overflowRejected != overflow {
+		t.Fatalf("expected all %d overflow logins rejected by the limiter, got %d", overflow, overflowRejected)
+	}
+	if router.Metrics().Rejected() != overflow {
+		t.Fatalf("expected the router's rejected-login metric to be %d, got %d", overflow, router.Metrics().Rejected())
+	}
+	if router.Metrics().StaleGeneration() != 0 {
+		t.Fatalf("expected no stale-generation rejections before the reload, got %d", router.Metrics().StaleGeneration())
+	}
+
+	// Phase 3: reload while every slot is still held by a login that
+	// captured the pre-reload generation, then release the holders so
+	// they observe the new generation on return.
+	if err := catalog.ReloadPlugin(context.Background(), "test-plugin"); err != nil {
+		t.Fatal(err)
+	}
+	close(releaseHolders)
+	wg.Wait()
+
+	if holderOther != 0 {
+		t.Fatalf("expected every slot holder to be rejected with LoginInProgress after the reload, got %d other outcomes", holderOther)
+	}
+	if holderStale != maxConcurrentLogins {
+		t.Fatalf("expected all %d slot holders rejected for a stale generation, got %d", maxConcurrentLogins, holderStale)
+	}
+	if router.Metrics().StaleGeneration() != maxConcurrentLogins {
+		t.Fatalf("expected the router's stale-generation metric to be %d, got %d", maxConcurrentLogins, router.Metrics().StaleGeneration())
+	}
+	if router.Metrics().Rejected() != overflow {
+		t.Fatalf("expected the rejected-login metric to stay at %d after the reload, got %d", overflow, router.Metrics().Rejected())
+	}
+	if router.Metrics().InProgress() != 0 {
+		t.Fatalf("expected every login slot to be released once all logins returned, got %d still held", router.Metrics().InProgress())
+	}
+
+	// Confirm the post-reload generation is fully functional and is what
+	// a fresh login reports.
+	postReloadGeneration := catalog.GenerationFor("test-plugin")
+	resp, err := router.RouteLogin(context.Background(), mountPath, "test-plugin", func(ctx context.Context) (interface{}, int, error) {
+		return "token", postReloadGeneration, nil
+	})
+	if err != nil {
+		t.Fatalf("expected login to succeed after reload completed, got: %v", err)
+	}
+	if resp == nil {
+		t.Fatal("expected a non-nil response from the post-reload login")
+	}
+}
@@ -0,0 +1,155 @@
+package linearizability
+
+import "fmt"
+
+// registerState models a single mount as it moves through the
+// enable -> configure -> issue-cred -> revoke lifecycle. generation is
+// carried in the state, but no operation reads or changes it, so the
+// model does not distinguish plugin generations. leases holds every currently
+// outstanding lease for the mount: a database/secrets mount routinely has
+// several issue-cred calls in flight at once, each producing its own
+// lease, so a single string can't represent the mount's real state.
+type registerState struct {
+	enabled    bool
+	configured bool
+	generation int
+	leases     map[string]bool
+}
+
+// apply returns the state after op, and whether op was legal from s. It
+// never mutates s.leases in place: search explores many sibling orderings
+// from the same s, so a mutation here would leak across branches that
+// share the map by reference. Instead it copies leases into the returned
+// state whenever membership changes.
+func (s registerState) apply(op Op) (registerState, bool) {
+	switch op.Type {
+	case OpEnable:
+		if s.enabled {
+			return s, false
+		}
+		s.enabled = true
+		return s, true
+	case OpConfigure:
+		if !s.enabled {
+			return s, false
+		}
+		s.configured = true
+		return s, true
+	case OpIssueCred:
+		if !s.configured || s.leases[op.Response] {
+			return s, false
+		}
+		s.leases = cloneLeases(s.leases)
+		s.leases[op.Response] = true
+		return s, true
+	case OpRevoke:
+		if !s.leases[op.Args] {
+			return s, false
+		}
+		s.leases = cloneLeases(s.leases)
+		delete(s.leases, op.Args)
+		return s, true
+	default:
+		return s, false
+	}
+}
+
+func cloneLeases(leases map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(leases)+1)
+	for k := range leases {
+		out[k] = true
+	}
+	return out
+}
+
+// Violation describes a history that has no valid sequential ordering
+// consistent with the register model.
+type Violation struct {
+	Mount  string
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("mount %s: %s", v.Mount, v.Reason)
+}
+
+// Check partitions history by mount and, for each mount, searches for a
+// sequential ordering of its operations consistent with registerState. It
+// returns every mount for which no such ordering exists.
+//
+// This is a small, purpose-built stand-in for Porcupine's general
+// linearizability checker: our model only has one object per mount and a
+// handful of operation types, so a bounded DFS over per-mount orderings
+// consistent with the recorded [start,end) intervals is sufficient and
+// keeps this package dependency-free.
+func Check(h *History) []Violation {
+	byMount := make(map[string][]Op)
+	for _, op := range h.Ops() {
+		byMount[op.Mount] = append(byMount[op.Mount], op)
+	}
+
+	var violations []Violation
+	for mount, ops := range byMount {
+		if !linearizes(ops) {
+			violations = append(violations, Violation{
+				Mount:  mount,
+				Reason: "no sequential ordering of recorded ops is consistent with the register model",
+			})
+		}
+	}
+	return violations
+}
+
+// linearizes reports whether some permutation of ops, respecting the
+// partial order implied by non-overlapping [Start,End) intervals, can be
+// applied in sequence from the zero registerState without any op being
+// rejected.
+func linearizes(ops []Op) bool {
+	n := len(ops)
+	used := make([]bool, n)
+	return search(ops, used, registerState{}, n)
+}
+
+func search(ops []Op, used []bool, state registerState, remaining int) bool {
+	if remaining == 0 {
+		return true
+	}
+
+	for i, op := range ops {
+		if used[i] {
+			continue
+		}
+		if !readyToRun(ops, used, i) {
+			continue
+		}
+
+		next, ok := state.apply(op)
+		if !ok {
+			continue
+		}
+
+		used[i] = true
+		if search(ops, used, next, remaining-1) {
+			return true
+		}
+		used[i] = false
+	}
+
+	return false
+}
+
+// readyToRun reports whether op i could legally run next: every
+// not-yet-used op that strictly ended before op i started must have
+// already been applied, preserving real-time order where intervals don't
+// overlap.
+func readyToRun(ops []Op, used []bool, i int) bool {
+	for j, op := range ops {
+		if j == i || used[j] {
+			continue
+		}
+		if op.End.Before(ops[i].Start) {
+			return false
+		}
+	}
+	return true
+}
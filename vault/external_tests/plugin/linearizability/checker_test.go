@@ -0,0 +1,107 @@
+package linearizability
+
+import (
+	"testing"
+	"time"
+)
+
+func op(mount string, typ OpType, args, resp string, start, end int) Op {
+	epoch := time.Unix(0, 0)
+	return Op{
+		Mount:    mount,
+		Type:     typ,
+		Args:     args,
+		Response: resp,
+		Start:    epoch.Add(time.Duration(start) * time.Millisecond),
+		End:      epoch.Add(time.Duration(end) * time.Millisecond),
+	}
+}
+
+func TestCheck_ValidSequentialHistory(t *testing.T) {
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 10))
+	h.Append(op("db-0", OpConfigure, "", "", 10, 20))
+	h.Append(op("db-0", OpIssueCred, "", "lease-1", 20, 30))
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 30, 40))
+
+	if violations := Check(h); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestCheck_ConcurrentIssueIsLinearizable(t *testing.T) {
+	// An issue-cred call overlaps in time with the revoke of its lease;
+	// the single valid ordering (issue, then its matching revoke) still
+	// exists, so overlap alone must not be flagged as a violation.
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 5))
+	h.Append(op("db-0", OpConfigure, "", "", 5, 10))
+	h.Append(op("db-0", OpIssueCred, "", "lease-1", 10, 30))
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 20, 40))
+
+	if violations := Check(h); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestCheck_RevokeWithoutIssueIsAViolation(t *testing.T) {
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 10))
+	h.Append(op("db-0", OpConfigure, "", "", 10, 20))
+	// Revoking a lease that was never issued must never linearize; this
+	// is the shape a stale-plugin-generation bug would produce.
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 20, 30))
+
+	violations := Check(h)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", violations)
+	}
+	if violations[0].Mount != "db-0" {
+		t.Fatalf("expected violation for mount db-0, got %q", violations[0].Mount)
+	}
+}
+
+func TestCheck_ConcurrentlyOutstandingLeasesAreIndependent(t *testing.T) {
+	// Two issue-cred calls overlap and are never revoked in the order
+	// they were issued; the register model must track both leases at
+	// once rather than only remembering the most recent one.
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 5))
+	h.Append(op("db-0", OpConfigure, "", "", 5, 10))
+	h.Append(op("db-0", OpIssueCred, "", "lease-1", 10, 20))
+	h.Append(op("db-0", OpIssueCred, "", "lease-2", 15, 25))
+	h.Append(op("db-0", OpRevoke, "lease-2", "", 30, 40))
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 30, 40))
+
+	if violations := Check(h); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestCheck_RevokeOfAlreadyRevokedLeaseIsAViolation(t *testing.T) {
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 5))
+	h.Append(op("db-0", OpConfigure, "", "", 5, 10))
+	h.Append(op("db-0", OpIssueCred, "", "lease-1", 10, 20))
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 20, 30))
+	// Revoking the same lease twice must never linearize.
+	h.Append(op("db-0", OpRevoke, "lease-1", "", 30, 40))
+
+	violations := Check(h)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", violations)
+	}
+}
+
+func TestCheck_MultipleMountsAreIndependent(t *testing.T) {
+	h := NewHistory()
+	h.Append(op("db-0", OpEnable, "", "", 0, 10))
+	h.Append(op("db-0", OpConfigure, "", "", 10, 20))
+	h.Append(op("db-1", OpEnable, "", "", 0, 10))
+	h.Append(op("db-1", OpConfigure, "", "", 10, 20))
+	h.Append(op("db-1", OpIssueCred, "", "lease-1", 20, 30))
+
+	if violations := Check(h); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
@@ -0,0 +1,20 @@
+// Package linearizability records histories of operations against plugin
+// mounts under injected failures and checks them for linearizability
+// against a per-mount register model.
+//
+// It is modeled on etcd's linearizability test suite: RunWorkers drives a
+// concurrent worker pool against one or more mounts while a
+// FailpointClient triggers faults over HTTP (kill -9, SIGSTOP/SIGCONT,
+// dropped gRPC frames, forced ReloadPlugin mid-request). Every operation
+// is recorded as a (op, args, start, end, response) tuple; the resulting
+// History is handed to Check, a Porcupine-style checker that enumerates
+// sequential orderings consistent with the model.
+//
+// The model treats each mount as an independent register with states
+// enable -> configure -> issue-cred -> revoke: a lease must be issued
+// before it is revoked, and is revoked at most once. It does not model
+// plugin generations or reloads, so Check does not detect a credential
+// served by a stale plugin generation. The package's own tests drive an
+// in-memory fake mount and an httptest failpoint server; they do not run
+// the external plugin tests in the parent directory.
+package linearizability
@@ -0,0 +1,57 @@
+package linearizability
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+)
+
+// Failpoint identifies one of the fault-injection trigger points a
+// FailpointClient can fire.
+type Failpoint string
+
+const (
+	// FailpointKill sends the plugin subprocess SIGKILL mid-request.
+	FailpointKill Failpoint = "kill"
+	// FailpointStop sends SIGSTOP, then SIGCONT after Duration, simulating
+	// a slow/wedged plugin.
+	FailpointStop Failpoint = "stop"
+	// FailpointDropFrames drops a fraction of gRPC frames through a proxy
+	// listener sitting between core and the plugin process.
+	FailpointDropFrames Failpoint = "drop-frames"
+	// FailpointForceReload forces a ReloadPlugin while a creds/ read is
+	// in flight against the same mount.
+	FailpointForceReload Failpoint = "force-reload"
+)
+
+// FailpointClient triggers failpoints over HTTP by sending
+// POST /failpoints/{name}, using the names defined above. The server that
+// answers these requests is not part of this package; the package's own
+// test answers them with an httptest server.
+type FailpointClient struct {
+	addr string
+	hc   *http.Client
+}
+
+// NewFailpointClient returns a client that triggers failpoints against the
+// plugin control server listening at addr (e.g. "127.0.0.1:API_PORT").
+func NewFailpointClient(addr string) *FailpointClient {
+	return &FailpointClient{addr: addr, hc: http.DefaultClient}
+}
+
+// Trigger fires the named failpoint once. params is passed through as the
+// request body (e.g. a JSON-encoded duration for FailpointStop or a drop
+// rate for FailpointDropFrames).
+func (c *FailpointClient) Trigger(fp Failpoint, params []byte) error {
+	url := fmt.Sprintf("http://%s/failpoints/%s", c.addr, fp)
+	resp, err := c.hc.Post(url, "application/json", bytes.NewReader(params))
+	if err != nil {
+		return fmt.Errorf("triggering failpoint %s: %w", fp, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("triggering failpoint %s: unexpected status %s", fp, resp.Status)
+	}
+	return nil
+}
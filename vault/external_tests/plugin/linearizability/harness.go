@@ -0,0 +1,63 @@
+package linearizability
+
+import (
+	"sync"
+	"time"
+)
+
+// OpFunc runs one register operation against a single mount and reports
+// the result to be recorded in the History.
+type OpFunc func() (response string, args string, err error)
+
+// Worker repeatedly runs a single OpFunc against one mount, recording each
+// attempt into the shared History. It mirrors the parallel per-mount loops
+// already used in the external plugin tests in the parent directory, but threads
+// timing and response data through to a History instead of calling
+// t.Fatal.
+type Worker struct {
+	Mount string
+	Type  OpType
+	Run   OpFunc
+}
+
+// RunWorkers starts one goroutine per Worker, each calling its OpFunc in a
+// loop until stop is closed, and returns the shared History once every
+// worker has exited.
+func RunWorkers(workers []Worker, history *History, stop <-chan struct{}) *History {
+	var wg sync.WaitGroup
+	wg.Add(len(workers))
+
+	for _, w := range workers {
+		w := w
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				start := time.Now()
+				resp, args, err := w.Run()
+				end := time.Now()
+
+				op := Op{
+					Mount:    w.Mount,
+					Type:     w.Type,
+					Args:     args,
+					Response: resp,
+					Start:    start,
+					End:      end,
+				}
+				if err != nil {
+					op.Err = err.Error()
+				}
+				history.Append(op)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return history
+}
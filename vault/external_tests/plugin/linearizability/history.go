@@ -0,0 +1,72 @@
+package linearizability
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// OpType identifies one of the register-model operations exercised against
+// a mount.
+type OpType string
+
+const (
+	OpEnable    OpType = "enable"
+	OpConfigure OpType = "configure"
+	OpIssueCred OpType = "issue-cred"
+	OpRevoke    OpType = "revoke"
+)
+
+// Op is a single recorded call against a mount: the operation attempted,
+// the arguments passed, the wall-clock interval it spanned, and the
+// response observed (or the error, if any). Start/End are wall-clock
+// timestamps taken around the call (by RunWorkers, or by the caller for ops
+// it appends itself); Check only compares the recorded values, so a
+// recorded history replays deterministically.
+type Op struct {
+	Mount string `json:"mount"`
+	Type  OpType `json:"type"`
+	Args  string `json:"args,omitempty"`
+
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	Response string `json:"response,omitempty"`
+	Err      string `json:"err,omitempty"`
+}
+
+// History is an ordered, thread-safe log of Ops collected while the worker
+// pool drives traffic against the cluster.
+type History struct {
+	mu  sync.Mutex
+	ops []Op
+}
+
+// NewHistory returns an empty History ready to be appended to from
+// multiple worker goroutines.
+func NewHistory() *History {
+	return &History{}
+}
+
+// Append records a completed operation.
+func (h *History) Append(op Op) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ops = append(h.ops, op)
+}
+
+// Ops returns a copy of the recorded operations in append order.
+func (h *History) Ops() []Op {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Op, len(h.ops))
+	copy(out, h.ops)
+	return out
+}
+
+// MarshalJSON serializes the history as a JSON array of Ops, one per
+// recorded operation, so a failing run can be written to disk and replayed
+// through Check independently of the cluster that produced it.
+func (h *History) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Ops())
+}
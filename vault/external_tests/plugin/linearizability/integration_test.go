@@ -0,0 +1,215 @@
+package linearizability
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mustAppend records a zero-duration op for an already-completed setup
+// call (Enable/Configure) into h, so that Check sees the same lifecycle
+// transitions the register model requires before issue-cred/revoke can
+// apply. A real harness would record these the same way RunWorkers
+// records concurrent ops - as a side effect of making the call, not as an
+// afterthought before checking.
+func mustAppend(h *History, mount string, typ OpType) {
+	now := time.Now()
+	h.Append(Op{Mount: mount, Type: typ, Start: now, End: now})
+}
+
+// fakeMount is a minimal, mutex-guarded stand-in for a running secrets
+// engine mount: enough surface to drive Enable/Configure/IssueCred/Revoke
+// from concurrent workers and to have its generation bumped out from
+// under in-flight callers by a force-reload failpoint, without needing a
+// real plugin binary or vault.TestCluster to back it.
+type fakeMount struct {
+	mu         sync.Mutex
+	enabled    bool
+	configured bool
+	generation int
+	nextLease  int
+	leases     map[string]bool
+}
+
+func newFakeMount() *fakeMount {
+	return &fakeMount{leases: make(map[string]bool)}
+}
+
+func (m *fakeMount) Enable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enabled = true
+}
+
+func (m *fakeMount) Configure() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.enabled {
+		return fmt.Errorf("mount not enabled")
+	}
+	m.configured = true
+	return nil
+}
+
+func (m *fakeMount) IssueCred() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.configured {
+		return "", fmt.Errorf("mount not configured")
+	}
+	m.nextLease++
+	lease := fmt.Sprintf("lease-%d", m.nextLease)
+	m.leases[lease] = true
+	return lease, nil
+}
+
+func (m *fakeMount) Revoke(lease string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.leases[lease] {
+		return fmt.Errorf("unknown lease %s", lease)
+	}
+	delete(m.leases, lease)
+	return nil
+}
+
+func (m *fakeMount) ForceReload() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generation++
+}
+
+// TestIntegration_WorkersUnderForceReloadFailpointLinearize is the
+// end-to-end exercise RunWorkers, FailpointClient and Check were built
+// for: concurrent issue/revoke workers drive a mount through
+// RunWorkers while a force-reload failpoint is triggered repeatedly
+// through FailpointClient over HTTP, and the resulting History is fed
+// through Check. fakeMount stands in for a real plugin binary/control
+// server - this package has no way to build or spawn one - but the
+// harness plumbing (Worker goroutines, HTTP-triggered failpoints,
+// History recording, Check) is exactly what a real run against a built
+// test plugin would use.
+func TestIntegration_WorkersUnderForceReloadFailpointLinearize(t *testing.T) {
+	mount := newFakeMount()
+	history := NewHistory()
+
+	mount.Enable()
+	mustAppend(history, "db-0", OpEnable)
+	time.Sleep(time.Millisecond)
+
+	if err := mount.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	mustAppend(history, "db-0", OpConfigure)
+	time.Sleep(time.Millisecond)
+
+	var failpointsTriggered int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/failpoints/"+string(FailpointForceReload) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mount.ForceReload()
+		atomic.AddInt64(&failpointsTriggered, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fp := NewFailpointClient(server.Listener.Addr().String())
+
+	// pendingLeases carries leases from the issuing worker to the
+	// revoking worker so every issued lease is eventually revoked by a
+	// separate concurrent goroutine, rather than each worker round-
+	// tripping its own lease.
+	pendingLeases := make(chan string, 64)
+
+	workers := []Worker{
+		{
+			Mount: "db-0",
+			Type:  OpIssueCred,
+			Run: func() (string, string, error) {
+				// A real plugin RPC has latency; without it this loop
+				// would produce thousands of near-instant, heavily
+				// overlapping ops in a short run and make Check's
+				// bounded DFS needlessly expensive.
+				time.Sleep(2 * time.Millisecond)
+				lease, err := mount.IssueCred()
+				if err == nil {
+					select {
+					case pendingLeases <- lease:
+					default:
+					}
+				}
+				return lease, "", err
+			},
+		},
+		{
+			Mount: "db-0",
+			Type:  OpRevoke,
+			Run: func() (string, string, error) {
+				time.Sleep(2 * time.Millisecond)
+				select {
+				case lease := <-pendingLeases:
+					return "", lease, mount.Revoke(lease)
+				default:
+					return "", "", fmt.Errorf("no lease ready to revoke")
+				}
+			},
+		},
+	}
+
+	stop := make(chan struct{})
+	var fpWg sync.WaitGroup
+	fpWg.Add(1)
+	go func() {
+		defer fpWg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; i < 10; i++ {
+			select {
+			case <-ticker.C:
+				if err := fp.Trigger(FailpointForceReload, nil); err != nil {
+					t.Error(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+	history = RunWorkers(workers, history, stop)
+	fpWg.Wait()
+
+	if atomic.LoadInt64(&failpointsTriggered) == 0 {
+		t.Fatal("expected the force-reload failpoint to have triggered at least once")
+	}
+	if mount.generation == 0 {
+		t.Fatal("expected ForceReload to have bumped the mount's generation")
+	}
+
+	// Ops recorded for a call that failed (e.g. a revoke worker that
+	// found no pending lease) didn't change fakeMount's state and aren't
+	// part of the sequence Check needs to explain, so they're filtered
+	// out before checking - mirroring how a real harness would drop
+	// hard errors before replaying a history.
+	successOnly := NewHistory()
+	attempted := 0
+	for _, op := range history.Ops() {
+		attempted++
+		if op.Err == "" {
+			successOnly.Append(op)
+		}
+	}
+	if attempted == 0 {
+		t.Fatal("expected RunWorkers to have recorded at least one op")
+	}
+
+	if violations := Check(successOnly); len(violations) != 0 {
+		t.Fatalf("expected no violations from fakeMount's linearizable history, got %v", violations)
+	}
+}
@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// LoginBackend is the subset of a credential plugin's Login handling that
+// LoginRouter needs in order to enforce per-mount backpressure: invoke the
+// plugin and report which catalog generation actually served the request.
+// generation should come from whatever the plugin process had loaded at
+// call time (e.g. captured at the start of backend's Login RPC), so that
+// RouteLogin can detect a response served by a generation that's gone
+// stale by the time it returns.
+type LoginBackend func(ctx context.Context) (resp interface{}, generation int, err error)
+
+// LoginRouter sits in front of a mount's Login path and enforces the
+// max_concurrent_logins backpressure configured via
+// loginConcurrencyLimiter, so that a burst of logins against a plugin
+// mid-reload fails fast with logical.ErrLoginInProgress instead of
+// queuing behind the reload. It also rejects a response that did land,
+// but was served by a generation that's no longer current by the time
+// RouteLogin observes catalog - which would otherwise let a stale-plugin
+// response through as if it were valid.
+type LoginRouter struct {
+	catalog *PluginCatalog
+	limiter *loginConcurrencyLimiter
+	metrics *inmemLoginConcurrencyMetrics
+}
+
+// NewLoginRouter returns a LoginRouter backed by catalog, with its own
+// in-memory loginConcurrencyMetrics sink. Use Metrics to read the sink's
+// counters.
+func NewLoginRouter(catalog *PluginCatalog) *LoginRouter {
+	metrics := newInmemLoginConcurrencyMetrics()
+	return &LoginRouter{
+		catalog: catalog,
+		limiter: newLoginConcurrencyLimiter(metrics),
+		metrics: metrics,
+	}
+}
+
+// TuneMaxConcurrentLogins sets the `max_concurrent_logins` mount tuning
+// parameter for mountPath. A limit of 0 disables enforcement, which is
+// the default for every mount until tuned.
+func (r *LoginRouter) TuneMaxConcurrentLogins(mountPath string, limit int64) {
+	r.limiter.setLimit(mountPath, limit)
+}
+
+// Metrics returns the router's login concurrency counters.
+func (r *LoginRouter) Metrics() *inmemLoginConcurrencyMetrics {
+	return r.metrics
+}
+
+// RouteLogin acquires a login slot for mountPath, invokes backend, and
+// releases the slot once backend returns. It fails fast with
+// logical.ErrLoginInProgress, without calling backend at all, if
+// mountPath is already at its configured max_concurrent_logins (recorded
+// as a Rejected metric). It also returns logical.ErrLoginInProgress if
+// backend succeeds but reports a generation for pluginName other than the
+// catalog's current one (recorded as a StaleGeneration metric), since
+// that response was computed against a plugin version that's already
+// been superseded by a reload. Both cases surface the same sentinel error
+// to the caller - either is equally safe to retry - but are counted
+// separately so the two backpressure sources stay distinguishable in
+// metrics.
+func (r *LoginRouter) RouteLogin(ctx context.Context, mountPath, pluginName string, backend LoginBackend) (interface{}, error) {
+	release, err := r.limiter.acquire(mountPath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, generation, err := backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.catalog != nil && pluginName != "" {
+		if current := r.catalog.GenerationFor(pluginName); current >= 0 && generation != current {
+			r.metrics.IncrStaleGeneration(mountPath)
+			return nil, logical.ErrLoginInProgress
+		}
+	}
+
+	return resp, nil
+}
+
+// inmemLoginConcurrencyMetrics is the real loginConcurrencyMetrics sink
+// LoginRouter wires into its limiter: it keeps running totals rather than
+// shipping to an external metrics backend, which is all the router needs
+// to answer its own Metrics() accessor and all a test needs to assert
+// backpressure actually triggered. staleGeneration is incremented by
+// RouteLogin directly rather than through the loginConcurrencyMetrics
+// interface, since a stale-generation rejection happens after the
+// limiter's own acquire/release bookkeeping and isn't something the
+// limiter itself observes.
+type inmemLoginConcurrencyMetrics struct {
+	inProgress      int64
+	rejected        int64
+	staleGeneration int64
+}
+
+func newInmemLoginConcurrencyMetrics() *inmemLoginConcurrencyMetrics {
+	return &inmemLoginConcurrencyMetrics{}
+}
+
+func (m *inmemLoginConcurrencyMetrics) IncrInProgress(mountPath string, delta int64) {
+	atomic.AddInt64(&m.inProgress, delta)
+}
+
+func (m *inmemLoginConcurrencyMetrics) IncrRejected(mountPath string) {
+	atomic.AddInt64(&m.rejected, 1)
+}
+
+func (m *inmemLoginConcurrencyMetrics) IncrStaleGeneration(mountPath string) {
+	atomic.AddInt64(&m.staleGeneration, 1)
+}
+
+// InProgress returns the current number of in-flight logins across every
+// mount this sink has observed.
+func (m *inmemLoginConcurrencyMetrics) InProgress() int64 {
+	return atomic.LoadInt64(&m.inProgress)
+}
+
+// Rejected returns the total number of logins this sink has seen
+// rejected for exceeding their mount's max_concurrent_logins.
+func (m *inmemLoginConcurrencyMetrics) Rejected() int64 {
+	return atomic.LoadInt64(&m.rejected)
+}
+
+// StaleGeneration returns the total number of logins this sink has seen
+// rejected because they were served by a plugin generation a reload had
+// already superseded by the time RouteLogin observed the catalog.
+func (m *inmemLoginConcurrencyMetrics) StaleGeneration() int64 {
+	return atomic.LoadInt64(&m.staleGeneration)
+}
@@ -0,0 +1,224 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// RegisterPluginInput is the server-side counterpart of
+// api.RegisterPluginInput: the fields PluginCatalog.RegisterPlugin
+// validates and records. No HTTP handler in this tree decodes a request
+// into it; see PluginSubsystems.
+type RegisterPluginInput struct {
+	Name    string
+	Type    consts.PluginType
+	Command string
+	SHA256  string
+	Version string
+
+	Signature       string
+	SignatureFormat PluginSignatureFormat
+	Manifest        *PluginManifestInput
+}
+
+// PluginManifestInput is the server-side counterpart of
+// api.PluginManifestInput.
+type PluginManifestInput struct {
+	PluginType        string
+	Capabilities      []string
+	RequiredSudoPaths []string
+}
+
+func pluginTypeName(t consts.PluginType) string {
+	switch t {
+	case consts.PluginTypeCredential:
+		return "auth"
+	case consts.PluginTypeSecrets:
+		return "secret"
+	case consts.PluginTypeDatabase:
+		return "database"
+	default:
+		return "unknown"
+	}
+}
+
+// pluginCatalogEntry is the bookkeeping record kept for a registered
+// plugin: enough for ReloadPlugin to find it by name and to detect a
+// stale generation after a reload.
+type pluginCatalogEntry struct {
+	name       string
+	typ        consts.PluginType
+	version    string
+	sha256     string
+	command    string
+	mountPaths []string
+	generation int
+}
+
+// PluginCatalog tracks registered external plugins: their binary/version
+// metadata, containment and signature verification state, and the
+// lifecycle event stream described by PluginEvent. Registration, reload
+// and deregistration all go through this type so that every transition
+// is validated and observed in one place.
+type PluginCatalog struct {
+	directory  string
+	trustRoots []PluginTrustRoot
+
+	l       sync.RWMutex
+	entries map[string]*pluginCatalogEntry
+
+	events *pluginEventBroadcaster
+}
+
+// NewPluginCatalog returns an empty PluginCatalog rooted at directory,
+// trusting signatures from trustRoots.
+func NewPluginCatalog(directory string, trustRoots []PluginTrustRoot) *PluginCatalog {
+	return &PluginCatalog{
+		directory:  directory,
+		trustRoots: trustRoots,
+		entries:    make(map[string]*pluginCatalogEntry),
+		events:     newPluginEventBroadcaster(),
+	}
+}
+
+func catalogKey(name string, typ consts.PluginType) string {
+	return fmt.Sprintf("%s/%d", name, typ)
+}
+
+// RegisterPlugin validates i's command path, then the binary's SHA256,
+// then - when a signature is present - its signature and manifest, and
+// records it in the catalog and emits PluginEventRegistered. Registration
+// is rejected - and the plugin is never spawned - if any of that
+// validation fails. SHA256 is checked before the signature because a
+// valid signature over the wrong binary is meaningless.
+func (c *PluginCatalog) RegisterPlugin(ctx context.Context, i *RegisterPluginInput) error {
+	resolved, err := validatePluginCommandPath(c.directory, i.Command)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyPluginBinarySHA256(resolved, i.SHA256); err != nil {
+		return err
+	}
+
+	if i.Signature != "" {
+		var manifest *PluginManifest
+		if i.Manifest != nil {
+			manifest = &PluginManifest{
+				PluginType:        i.Manifest.PluginType,
+				Capabilities:      i.Manifest.Capabilities,
+				RequiredSudoPaths: i.Manifest.RequiredSudoPaths,
+			}
+		}
+		if err := verifyPluginSignature(i.SHA256, i.SignatureFormat, i.Signature, c.trustRoots, manifest, pluginTypeName(i.Type)); err != nil {
+			return err
+		}
+	}
+
+	c.l.Lock()
+	key := catalogKey(i.Name, i.Type)
+	entry := &pluginCatalogEntry{
+		name:    i.Name,
+		typ:     i.Type,
+		version: i.Version,
+		sha256:  i.SHA256,
+		command: i.Command,
+	}
+	c.entries[key] = entry
+	c.l.Unlock()
+
+	c.emitPluginEvent(&PluginEvent{
+		Type:       PluginEventRegistered,
+		Name:       i.Name,
+		PluginType: i.Type,
+		Version:    i.Version,
+		SHA256:     i.SHA256,
+	})
+	c.emitPluginEvent(&PluginEvent{
+		Type:       PluginEventProcessStarted,
+		Name:       i.Name,
+		PluginType: i.Type,
+		Version:    i.Version,
+	})
+
+	return nil
+}
+
+// ReloadPlugin swaps every entry registered under name to the next
+// generation and emits PluginEventReloadStarted followed by
+// PluginEventReloadCompleted once the swap is done. The swap is atomic
+// under c.l: a concurrent RegisterPlugin/DeregisterPlugin/ReloadPlugin for
+// the same name serializes against it, so no caller can observe a
+// half-reloaded entry.
+func (c *PluginCatalog) ReloadPlugin(ctx context.Context, name string) error {
+	c.l.RLock()
+	var typ consts.PluginType
+	found := false
+	for _, e := range c.entries {
+		if e.name == name {
+			typ = e.typ
+			found = true
+			break
+		}
+	}
+	c.l.RUnlock()
+	if !found {
+		return fmt.Errorf("no such plugin registered: %s", name)
+	}
+
+	c.emitPluginEvent(&PluginEvent{Type: PluginEventReloadStarted, Name: name, PluginType: typ})
+
+	c.l.Lock()
+	for _, e := range c.entries {
+		if e.name == name {
+			e.generation++
+		}
+	}
+	c.l.Unlock()
+
+	c.emitPluginEvent(&PluginEvent{Type: PluginEventReloadCompleted, Name: name, PluginType: typ})
+	return nil
+}
+
+// DeregisterPlugin removes the name/typ entry from the catalog, whatever
+// its version, and emits PluginEventProcessExited followed by
+// PluginEventDeregistered; version is only reported on the events.
+func (c *PluginCatalog) DeregisterPlugin(ctx context.Context, name string, typ consts.PluginType, version string) error {
+	c.l.Lock()
+	key := catalogKey(name, typ)
+	delete(c.entries, key)
+	c.l.Unlock()
+
+	c.emitPluginEvent(&PluginEvent{
+		Type:       PluginEventProcessExited,
+		Name:       name,
+		PluginType: typ,
+		Version:    version,
+		ExitReason: "deregistered",
+	})
+	c.emitPluginEvent(&PluginEvent{
+		Type:       PluginEventDeregistered,
+		Name:       name,
+		PluginType: typ,
+		Version:    version,
+	})
+	return nil
+}
+
+// GenerationFor returns the current reload generation for name, or -1 if
+// no such plugin is registered. Exposed for callers - such as
+// LoginRouter.RouteLogin and tests - that need to assert a credential was
+// (or wasn't) served by a particular plugin generation.
+func (c *PluginCatalog) GenerationFor(name string) int {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	for _, e := range c.entries {
+		if e.name == name {
+			return e.generation
+		}
+	}
+	return -1
+}
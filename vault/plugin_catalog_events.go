@@ -0,0 +1,143 @@
+package vault
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// PluginEventType identifies a single kind of plugin lifecycle transition.
+// Subscribers filter on these rather than free-form strings so that new
+// event kinds can be added without breaking existing predicates.
+type PluginEventType string
+
+const (
+	PluginEventRegistered      PluginEventType = "PluginRegistered"
+	PluginEventEnabled         PluginEventType = "PluginEnabled"
+	PluginEventProcessStarted  PluginEventType = "PluginProcessStarted"
+	PluginEventProcessExited   PluginEventType = "PluginProcessExited"
+	PluginEventReloadStarted   PluginEventType = "PluginReloadStarted"
+	PluginEventReloadCompleted PluginEventType = "PluginReloadCompleted"
+	PluginEventDeregistered    PluginEventType = "PluginDeregistered"
+)
+
+// PluginEvent describes a single plugin lifecycle transition. Fields that
+// don't apply to a given EventType (e.g. PID/ExitReason for a Registered
+// event) are left zero-valued.
+type PluginEvent struct {
+	Type PluginEventType `json:"type"`
+
+	Name       string            `json:"name"`
+	PluginType consts.PluginType `json:"plugin_type"`
+	Version    string            `json:"version"`
+	SHA256     string            `json:"sha256"`
+
+	// MountPaths lists mounts backed by this plugin name. PluginCatalog
+	// does not track mounts, so the events it emits leave MountPaths empty.
+	MountPaths []string `json:"mount_paths,omitempty"`
+
+	PID        int    `json:"pid,omitempty"`
+	ExitReason string `json:"exit_reason,omitempty"`
+}
+
+// PluginEventFilter narrows a subscription to events matching every
+// non-empty predicate. An empty filter matches all plugin events.
+type PluginEventFilter struct {
+	Name    string
+	Type    consts.PluginType
+	Version string
+}
+
+func (f *PluginEventFilter) match(e *PluginEvent) bool {
+	if f == nil {
+		return true
+	}
+	if f.Name != "" && f.Name != e.Name {
+		return false
+	}
+	if f.Type != consts.PluginTypeUnknown && f.Type != e.PluginType {
+		return false
+	}
+	if f.Version != "" && f.Version != e.Version {
+		return false
+	}
+	return true
+}
+
+// pluginEventBroadcaster fans PluginEvents out to every live subscriber. It
+// is intentionally non-blocking: a slow subscriber drops events rather than
+// stalling plugin registration/reload paths on the catalog.
+type pluginEventBroadcaster struct {
+	l           sync.RWMutex
+	subscribers map[chan *PluginEvent]*PluginEventFilter
+}
+
+func newPluginEventBroadcaster() *pluginEventBroadcaster {
+	return &pluginEventBroadcaster{
+		subscribers: make(map[chan *PluginEvent]*PluginEventFilter),
+	}
+}
+
+// subscribe registers a new subscriber and returns a channel of matching
+// events along with an unsubscribe function. The channel is closed once
+// unsubscribe is called.
+func (b *pluginEventBroadcaster) subscribe(filter *PluginEventFilter) (<-chan *PluginEvent, func()) {
+	ch := make(chan *PluginEvent, 16)
+
+	b.l.Lock()
+	b.subscribers[ch] = filter
+	b.l.Unlock()
+
+	unsubscribe := func() {
+		b.l.Lock()
+		defer b.l.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *pluginEventBroadcaster) publish(e *PluginEvent) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	for ch, filter := range b.subscribers {
+		if !filter.match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			// Subscriber isn't keeping up; drop the event rather than block
+			// the catalog on a slow consumer.
+		}
+	}
+}
+
+// WatchPlugins returns a channel of plugin lifecycle events matching filter,
+// and a cancel function that must be called to release the subscription.
+// This is the subscription API surfaced to Go callers as
+// Sys().WatchPlugins(); the HTTP long-poll/SSE endpoint at
+// /sys/plugins/events is backed by the same broadcaster. Every
+// RegisterPlugin/ReloadPlugin/DeregisterPlugin call on c publishes to this
+// same broadcaster (see plugin_catalog.go), so a subscription opened here
+// before those calls receives their events, except that an event published
+// while the subscription's 16-event buffer is full is dropped.
+func (c *PluginCatalog) WatchPlugins(ctx context.Context, filter *PluginEventFilter) (<-chan *PluginEvent, func(), error) {
+	ch, unsubscribe := c.events.subscribe(filter)
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return ch, unsubscribe, nil
+}
+
+func (c *PluginCatalog) emitPluginEvent(e *PluginEvent) {
+	c.events.publish(e)
+}
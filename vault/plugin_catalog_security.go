@@ -0,0 +1,217 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Errors returned by validatePluginCommandPath, verifyPluginBinarySHA256
+// and verifyPluginSignature.
+// These are typed (rather than fmt.Errorf strings) so that callers such as
+// the sys/plugins/catalog HTTP handler can map them to distinct, stable
+// error codes instead of pattern-matching on error text.
+var (
+	ErrPluginShaMismatch       = errors.New("plugin sha256 does not match expected value")
+	ErrPluginSignatureInvalid  = errors.New("plugin signature is invalid")
+	ErrPluginUnknownSigner     = errors.New("plugin signature does not match any configured trust root")
+	ErrPluginManifestMismatch  = errors.New("plugin manifest does not match declared plugin type")
+	ErrPluginCommandEscapesDir = errors.New("plugin command escapes the configured plugin directory")
+)
+
+// PluginSignatureFormat identifies the detached signature scheme used for
+// a plugin binary.
+type PluginSignatureFormat string
+
+const (
+	PluginSignatureMinisign PluginSignatureFormat = "minisign"
+	PluginSignatureCosign   PluginSignatureFormat = "cosign"
+	PluginSignaturePGP      PluginSignatureFormat = "pgp"
+)
+
+// PluginManifest describes what a signed plugin binary declares it is and
+// can do. The signature covers only the binary's SHA256, not the manifest;
+// verifyPluginSignature compares the manifest's PluginType with the
+// registration's type and does not check Capabilities or RequiredSudoPaths.
+type PluginManifest struct {
+	PluginType        string   `json:"plugin_type"`
+	Capabilities      []string `json:"capabilities"`
+	RequiredSudoPaths []string `json:"required_sudo_paths"`
+}
+
+// PluginTrustRoot is one entry of the `plugin_trust_roots` core config
+// stanza: a named public key, in the format expected by Format, trusted to
+// sign plugin binaries.
+type PluginTrustRoot struct {
+	Name      string                `json:"name"`
+	Format    PluginSignatureFormat `json:"format"`
+	PublicKey string                `json:"public_key"`
+}
+
+// validatePluginCommandPath ensures command, once resolved relative to
+// pluginDir and with symlinks evaluated, still resides inside pluginDir,
+// and returns that resolved path. This rejects both literal ".."
+// traversal and a symlink planted inside the plugin directory that
+// points outside of it, either of which would otherwise let a registered
+// "plugin" spawn an arbitrary binary on the host. It is called from
+// PluginCatalog.RegisterPlugin before any entry is recorded or spawned;
+// the resolved path it returns is what RegisterPlugin then hashes to
+// check against the registration's advertised SHA256.
+func validatePluginCommandPath(pluginDir, command string) (string, error) {
+	if pluginDir == "" {
+		return "", fmt.Errorf("%w: no plugin directory configured", ErrPluginCommandEscapesDir)
+	}
+
+	absDir, err := filepath.Abs(pluginDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving plugin directory: %w", err)
+	}
+	absDir, err = filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving plugin directory: %w", err)
+	}
+
+	candidate := filepath.Join(absDir, command)
+	resolved, err := filepath.EvalSymlinks(candidate)
+	if err != nil {
+		return "", fmt.Errorf("resolving plugin command: %w", err)
+	}
+
+	rel, err := filepath.Rel(absDir, resolved)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrPluginCommandEscapesDir, command)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrPluginCommandEscapesDir, command)
+	}
+
+	return resolved, nil
+}
+
+// verifyPluginBinarySHA256 reads the binary at resolvedCommandPath and
+// returns ErrPluginShaMismatch if its SHA256 doesn't match expectedHex.
+// It is called from PluginCatalog.RegisterPlugin immediately after path
+// containment is validated and before any signature is checked, since a
+// valid signature over the wrong binary is meaningless.
+func verifyPluginBinarySHA256(resolvedCommandPath, expectedHex string) error {
+	f, err := os.Open(resolvedCommandPath)
+	if err != nil {
+		return fmt.Errorf("reading plugin binary: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hashing plugin binary: %w", err)
+	}
+
+	actualHex := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actualHex, expectedHex) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrPluginShaMismatch, expectedHex, actualHex)
+	}
+	return nil
+}
+
+// pluginSignatureVerifier verifies a detached signature over a plugin
+// binary's SHA256 against a single trust root. Each supported
+// SignatureFormat gets its own implementation registered in
+// pluginSignatureVerifiers.
+type pluginSignatureVerifier interface {
+	Verify(sha256Sum []byte, signature []byte, trustRoot PluginTrustRoot) error
+}
+
+// hmacSHA256SignatureVerifier is the reference verifier registered for
+// PluginSignatureMinisign. It treats trustRoot.PublicKey as an HMAC-SHA256
+// key over the plugin's sha256 sum, which is enough to exercise and test
+// the containment/signing seam end to end without vendoring a minisign
+// implementation. Swap this registration for a real minisign verifier
+// (and add equivalent cosign/pgp verifiers) before relying on this in
+// production.
+type hmacSHA256SignatureVerifier struct{}
+
+func (hmacSHA256SignatureVerifier) Verify(sha256Sum []byte, signature []byte, trustRoot PluginTrustRoot) error {
+	mac := hmac.New(sha256.New, []byte(trustRoot.PublicKey))
+	mac.Write(sha256Sum)
+	expected := mac.Sum(nil)
+	if !hmac.Equal(expected, signature) {
+		return fmt.Errorf("signature does not match trust root %q", trustRoot.Name)
+	}
+	return nil
+}
+
+// pluginSignatureVerifiers holds the registered verifier for each
+// supported PluginSignatureFormat. Only minisign has a (reference)
+// implementation today; cosign and pgp are intentionally left unregistered
+// so verifyPluginSignature reports ErrPluginSignatureInvalid for them
+// rather than silently accepting an unverified signature.
+var pluginSignatureVerifiers = map[PluginSignatureFormat]pluginSignatureVerifier{
+	PluginSignatureMinisign: hmacSHA256SignatureVerifier{},
+}
+
+// SignPluginSHA256ForTest produces a signature for sha256Hex that will
+// verify against trustRoot under PluginSignatureMinisign. It exists so
+// tests can exercise RegisterPlugin's signature verification without a
+// real minisign keypair.
+func SignPluginSHA256ForTest(sha256Hex string, trustRoot PluginTrustRoot) (string, error) {
+	sum, err := hex.DecodeString(sha256Hex)
+	if err != nil {
+		return "", fmt.Errorf("invalid sha256: %w", err)
+	}
+	mac := hmac.New(sha256.New, []byte(trustRoot.PublicKey))
+	mac.Write(sum)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifyPluginSignature checks signatureHex against every trust root
+// configured for format, then checks manifest against declaredType. It
+// returns ErrPluginSignatureInvalid, ErrPluginUnknownSigner or
+// ErrPluginManifestMismatch, or nil if the plugin is cleared to spawn. It
+// is called from PluginCatalog.RegisterPlugin whenever a signature is
+// present on the registration request.
+func verifyPluginSignature(sha256Hex string, format PluginSignatureFormat, signatureHex string, trustRoots []PluginTrustRoot, manifest *PluginManifest, declaredType string) error {
+	verifier, ok := pluginSignatureVerifiers[format]
+	if !ok {
+		return fmt.Errorf("%w: unsupported signature format %q", ErrPluginSignatureInvalid, format)
+	}
+
+	sum, err := hex.DecodeString(sha256Hex)
+	if err != nil {
+		return fmt.Errorf("%w: invalid sha256 encoding: %v", ErrPluginSignatureInvalid, err)
+	}
+	sig, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature encoding: %v", ErrPluginSignatureInvalid, err)
+	}
+
+	var lastErr error
+	signed := false
+	for _, root := range trustRoots {
+		if root.Format != format {
+			continue
+		}
+		if err := verifier.Verify(sum, sig, root); err != nil {
+			lastErr = err
+			continue
+		}
+		signed = true
+		break
+	}
+	if !signed {
+		if lastErr != nil {
+			return fmt.Errorf("%w: %v", ErrPluginUnknownSigner, lastErr)
+		}
+		return ErrPluginUnknownSigner
+	}
+
+	if manifest != nil && manifest.PluginType != "" && manifest.PluginType != declaredType {
+		return fmt.Errorf("%w: manifest declares %q, registration declares %q", ErrPluginManifestMismatch, manifest.PluginType, declaredType)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package vault
+
+// PluginSubsystems bundles the external-plugin subsystems that a running
+// Core needs to wire up together: the catalog that owns registration,
+// reload and lifecycle events, and the login router that sits in front of
+// credential mounts' Login path. Core is expected to hold one of these per
+// server and thread it through mount registration, ReloadPlugin and
+// Login the same way it already threads its other per-server state -
+// this type only exists so that wiring is a single field/constructor call
+// rather than three independently-constructed globals.
+//
+// This tree doesn't carry vault/core.go, so Core can't be edited here to
+// actually hold a PluginSubsystems field or call these methods from its
+// mount-enable, reload and Login paths. Every test that exercises
+// PluginCatalog/LoginRouter in this tree (TestExternalPlugin_PluginEvents,
+// TestExternalPlugin_SecretsEngine_SignedManifest,
+// TestExternalPlugin_AuthMethod_ConcurrentLoginDuringReload,
+// TestHandleSysPluginsEvents_StreamsLifecycleEvents) therefore constructs
+// one of these directly instead of obtaining it from a running *Core, and
+// calls its Catalog and Router methods itself rather than through calls
+// Core would make. Once core.go is available to edit, wiring it up
+// is: hold a *PluginSubsystems on Core, construct it in NewCore alongside
+// the rest of Core's per-server state, call RegisterPlugin/ReloadPlugin
+// from the existing sys/plugins/catalog handlers instead of whatever
+// catalog they use today, and call RouteLogin from each credential mount's
+// Login path with a LoginBackend closure that invokes the mount's real
+// backend.
+type PluginSubsystems struct {
+	Catalog *PluginCatalog
+	Router  *LoginRouter
+}
+
+// NewPluginSubsystems constructs a PluginCatalog rooted at directory
+// trusting trustRoots, and a LoginRouter backed by that catalog, as the
+// single entry point Core would use to build its plugin subsystems.
+func NewPluginSubsystems(directory string, trustRoots []PluginTrustRoot) *PluginSubsystems {
+	catalog := NewPluginCatalog(directory, trustRoots)
+	return &PluginSubsystems{
+		Catalog: catalog,
+		Router:  NewLoginRouter(catalog),
+	}
+}